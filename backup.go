@@ -0,0 +1,233 @@
+package ghostpass
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/awnumar/memguard"
+)
+
+// BackupSchemaVersion is written into every backup manifest so a future
+// ghostpass release can tell which layout it's reading.
+const BackupSchemaVersion = 1
+
+// BackupManifestEntry describes one store inside a backup archive: enough
+// metadata to verify and re-open it without having decrypted anything yet.
+type BackupManifestEntry struct {
+	Name             string `json:"name"`
+	SchemaVersion    int    `json:"schema_version"`
+	KDFN             int    `json:"kdf_n"`
+	KDFR             int    `json:"kdf_r"`
+	KDFP             int    `json:"kdf_p"`
+	Timestamp        int64  `json:"timestamp"`
+	CiphertextSHA256 string `json:"ciphertext_sha256"`
+}
+
+// BackupManifest is the self-describing header written as manifest.json at the
+// root of every ghostpass backup archive.
+type BackupManifest struct {
+	Version int                   `json:"version"`
+	Stores  []BackupManifestEntry `json:"stores"`
+}
+
+// Backup writes a versioned tar.gz archive of stores to w: a manifest.json
+// (store names, schema version, KDF parameters, timestamps, per-store
+// ciphertext SHA-256) followed by each store's on-disk envelope, unmodified
+// and still encrypted under its own master key.
+func Backup(stores []*Store, w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest := BackupManifest{Version: BackupSchemaVersion}
+	now := time.Now().Unix()
+
+	type rawEntry struct {
+		name string
+		raw  []byte
+	}
+	entries := make([]rawEntry, 0, len(stores))
+
+	for _, store := range stores {
+		raw, err := ioutil.ReadFile(storePath(store.Name))
+		if err != nil {
+			return fmt.Errorf("reading store `%s`: %w", store.Name, err)
+		}
+
+		var env envelope
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return fmt.Errorf("store `%s` has a corrupted envelope: %w", store.Name, err)
+		}
+
+		sum := sha256.Sum256(raw)
+		manifest.Stores = append(manifest.Stores, BackupManifestEntry{
+			Name:             store.Name,
+			SchemaVersion:    BackupSchemaVersion,
+			KDFN:             env.KDFN,
+			KDFR:             env.KDFR,
+			KDFP:             env.KDFP,
+			Timestamp:        now,
+			CiphertextSHA256: hex.EncodeToString(sum[:]),
+		})
+		entries = append(entries, rawEntry{name: store.Name, raw: raw})
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarEntry(tw, "manifest.json", manifestBytes); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := writeTarEntry(tw, e.name+".gpstore", e.raw); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data))}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Restore reads a backup archive written by Backup and recreates each store it
+// contains. keyFor is invoked once per store name to obtain that store's
+// master key, since every store in an archive may have been encrypted under
+// its own key independently of the others. The returned stores are decrypted
+// and ready to CommitStore(), but aren't written to disk until the caller
+// does so.
+func Restore(r io.Reader, keyFor func(name string) (*memguard.Enclave, error)) ([]*Store, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a ghostpass backup archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var manifest BackupManifest
+	rawByName := map[string][]byte{}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+
+		if hdr.Name == "manifest.json" {
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return nil, fmt.Errorf("corrupted backup manifest: %w", err)
+			}
+			continue
+		}
+		rawByName[hdr.Name] = data
+	}
+
+	if manifest.Version == 0 {
+		return nil, fmt.Errorf("backup archive is missing its manifest")
+	}
+
+	stores := make([]*Store, 0, len(manifest.Stores))
+	for _, entry := range manifest.Stores {
+		raw, ok := rawByName[entry.Name+".gpstore"]
+		if !ok {
+			return nil, fmt.Errorf("backup archive manifest references missing store `%s`", entry.Name)
+		}
+
+		sum := sha256.Sum256(raw)
+		if hex.EncodeToString(sum[:]) != entry.CiphertextSHA256 {
+			return nil, fmt.Errorf("store `%s` failed integrity check; archive may be corrupted", entry.Name)
+		}
+
+		var env envelope
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return nil, fmt.Errorf("store `%s` has a corrupted envelope: %w", entry.Name, err)
+		}
+
+		salt, err := base64.StdEncoding.DecodeString(env.Salt)
+		if err != nil {
+			return nil, err
+		}
+		blob, err := base64.StdEncoding.DecodeString(env.Blob)
+		if err != nil {
+			return nil, err
+		}
+
+		masterkey, err := keyFor(entry.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		buf, err := masterkey.Open()
+		if err != nil {
+			return nil, err
+		}
+		key, err := deriveKey(buf.Bytes(), salt)
+		buf.Destroy()
+		if err != nil {
+			return nil, err
+		}
+
+		fields, err := decryptFields(key, blob)
+		if err != nil {
+			return nil, fmt.Errorf("store `%s`: incorrect master key or corrupted backup: %w", entry.Name, err)
+		}
+
+		stores = append(stores, &Store{Name: entry.Name, salt: salt, key: key, fields: fields})
+	}
+
+	return stores, nil
+}
+
+// RotateKey decrypts every field in store `name` under oldKey, re-derives a
+// fresh salt/key from newKey, and writes the rotated store to disk atomically
+// (CommitStore's temp file + rename) so a crash mid-rotation can never leave a
+// corrupted store behind.
+func RotateKey(name string, oldKey, newKey *memguard.Enclave) error {
+	store, err := OpenStore(name, oldKey)
+	if err != nil {
+		return err
+	}
+
+	salt, err := newSalt()
+	if err != nil {
+		return err
+	}
+
+	buf, err := newKey.Open()
+	if err != nil {
+		return err
+	}
+	key, err := deriveKey(buf.Bytes(), salt)
+	buf.Destroy()
+	if err != nil {
+		return err
+	}
+
+	store.salt = salt
+	store.key = key
+	return store.CommitStore()
+}
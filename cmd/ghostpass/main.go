@@ -4,26 +4,55 @@ import (
     "os"
     "log"
     "fmt"
+    "time"
     "bufio"
+    "bytes"
     "strings"
     "errors"
     "syscall"
+    "io"
     "io/ioutil"
     "path/filepath"
+    "encoding/json"
 
     "github.com/urfave/cli/v2"
     "github.com/fatih/color"
     "github.com/manifoldco/promptui"
     "github.com/awnumar/memguard"
     "github.com/olekukonko/tablewriter"
+    "github.com/atotto/clipboard"
     "github.com/ghostpass/ghostpass"
+    "github.com/ghostpass/ghostpass/keyring"
     "golang.org/x/crypto/ssh/terminal"
 )
 
 const (
 	Description string = "Privacy-First Secrets Management Cryptosystem"
+
+	// default amount of time a master key is allowed to live in the OS keyring before it must be
+	// re-entered, used when --keyring-ttl/GHOSTPASS_KEYRING_TTL isn't given
+	DefaultKeyringTTL time.Duration = 1 * time.Hour
+
+	// plainsight codec used by `export` when --codec isn't given; the markov/text-substitution
+	// codec registered first and remains the safest default cover medium
+	DefaultPlainsightCodec string = "markov"
+
+	// default rendering for `view` when --output isn't given
+	DefaultOutputFormat string = "table"
+
+	// default length for `gen`/`add --generate` when --length isn't given
+	DefaultGeneratedLength int = 20
+
+	// shorthand a user can type at the `add` password prompt to generate one instead
+	GenerateShorthand string = "!gen"
 )
 
+// globals, set once argv is parsed, so helpers outside of an Action can still honor them
+var noKeyring bool
+var batchMode bool
+var autoYes bool
+var keyringTTL time.Duration
+
 
 // Helper for displaying banner. TODO: quiet down if set
 func Banner() {
@@ -58,6 +87,235 @@ func ReadKeyFromStdin() (*memguard.Enclave, error) {
 }
 
 
+// Resolves the master key for a given secret store, preferring a previously cached
+// key from the OS keyring (macOS Keychain, GNOME Keyring / KWallet, Windows Credential
+// Manager) over prompting on stdin. Namespaces the keyring entry as `ghostpass:<store>`
+// so multiple stores don't collide. Falls back to stdin on a miss, and offers to persist
+// the newly entered key back into the keyring unless --no-keyring was passed.
+func ResolveMasterKey(name string) (*memguard.Enclave, error) {
+    service := "ghostpass:" + name
+
+    if !noKeyring {
+        if enclave, err := keyring.Get(service); err == nil {
+            return enclave, nil
+        }
+    }
+
+    if batchMode {
+        return ReadMasterKeyBatch("GHOSTPASS_MASTER_KEY_FILE")
+    }
+
+    fmt.Printf("> Master Key (will not be echoed): ")
+    masterkey, err := ReadKeyFromStdin()
+    fmt.Println()
+    if err != nil {
+        return nil, err
+    }
+
+    if !noKeyring {
+        prompt := promptui.Select{
+            Label: "Cache master key in the OS keyring so you aren't prompted again?",
+            Items: []string{"Yes", "No"},
+        }
+        if _, result, err := prompt.Run(); err == nil && result == "Yes" {
+            if err := keyring.Set(service, masterkey, keyringTTL); err != nil {
+                color.Yellow("[!] Failed to cache master key in keyring: %s\n", err)
+            }
+        }
+    }
+
+    return masterkey, nil
+}
+
+
+// stdinReader is the single shared reader every batch/non-interactive stdin read goes
+// through. bufio.Reader buffers ahead of whatever it's asked to read, so constructing a
+// fresh one per call (as this used to) silently swallows whatever of a multi-line pipe
+// (e.g. rotate-key's old key followed by its new key) the first read didn't ask for.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// Resolves the master key for --batch/GHOSTPASS_BATCH=1 mode: from the file named by the
+// keyFileEnv environment variable if set, otherwise the next line off of stdinReader. Never
+// touches a TTY prompt, so it's safe to run from scripts and CI. keyFileEnv is a parameter
+// rather than a hardcoded GHOSTPASS_MASTER_KEY_FILE so callers needing more than one key in
+// the same invocation (rotate-key's old and new key) can each read from their own file.
+func ReadMasterKeyBatch(keyFileEnv string) (*memguard.Enclave, error) {
+    var raw []byte
+
+    if path := os.Getenv(keyFileEnv); path != "" {
+        data, err := ioutil.ReadFile(path)
+        if err != nil {
+            return nil, err
+        }
+        raw = bytes.TrimRight(data, "\n")
+    } else {
+        line, err := stdinReader.ReadString('\n')
+        if err != nil && err != io.EOF {
+            return nil, err
+        }
+        raw = []byte(strings.TrimRight(line, "\n"))
+    }
+
+    key := memguard.NewBufferFromBytes(raw)
+    if key.Size() == 0 {
+        return nil, errors.New("no input received")
+    }
+    return key.Seal(), nil
+}
+
+
+// Reads a line of input interactively, prompting with label. In --batch mode there's no TTY
+// to prompt on, so a missing value is a hard error instead pointing the caller at the flag.
+func PromptLine(label string, flagHint string) (string, error) {
+    if batchMode {
+        return "", fmt.Errorf("%s not specified; pass %s explicitly in --batch mode", label, flagHint)
+    }
+
+    fmt.Printf("> %s: ", label)
+    text, err := stdinReader.ReadString('\n')
+    if err != nil {
+        return "", err
+    }
+    return strings.TrimSuffix(text, "\n"), nil
+}
+
+
+// A computed TOTP/OTP code alongside the window it's still valid for, surfaced by `ghostpass
+// otp` and as the live OTP column in `view`.
+type OTPCode struct {
+    Code string
+    RemainingSeconds int
+}
+
+// Renders a [service, username, password] field combo in the requested format so `view`
+// can be piped into shell exports or consumed by other tools under --batch. "table" is the
+// only format that touches a colorized banner; the rest print bare, script-friendly lines.
+// otp is nil when the field carries no TOTP seed.
+func WriteFieldOutput(format string, combo []string, otp *OTPCode) error {
+    service, username, password := combo[0], combo[1], combo[2]
+
+    switch format {
+    case "json":
+        fields := map[string]string{
+            "service": service,
+            "username": username,
+            "password": password,
+        }
+        if otp != nil {
+            fields["otp"] = otp.Code
+        }
+        b, err := json.Marshal(fields)
+        if err != nil {
+            return err
+        }
+        fmt.Println(string(b))
+    case "env":
+        fmt.Printf("%s_USERNAME=%s\n", EnvVarName(service), username)
+        fmt.Printf("%s_PASSWORD=%s\n", EnvVarName(service), password)
+        if otp != nil {
+            fmt.Printf("%s_OTP=%s\n", EnvVarName(service), otp.Code)
+        }
+    case "dotenv":
+        fmt.Printf("%s_USERNAME=%q\n", EnvVarName(service), username)
+        fmt.Printf("%s_PASSWORD=%q\n", EnvVarName(service), password)
+        if otp != nil {
+            fmt.Printf("%s_OTP=%q\n", EnvVarName(service), otp.Code)
+        }
+    default:
+        table := tablewriter.NewWriter(os.Stdout)
+        row := combo
+        if otp != nil {
+            table.SetHeader([]string{"Service", "Username", "Password", "OTP"})
+            row = append(append([]string{}, combo...), fmt.Sprintf("%s (%ds)", otp.Code, otp.RemainingSeconds))
+        } else {
+            table.SetHeader([]string{"Service", "Username", "Password"})
+        }
+        table.SetAutoMergeCells(true)
+        table.SetRowLine(true)
+        table.Append(row)
+        table.Render()
+    }
+
+    return nil
+}
+
+
+// Normalizes a service name into a shell-safe environment variable prefix for the env/dotenv
+// output formats, e.g. "my-service.com" -> "MY_SERVICE_COM".
+func EnvVarName(service string) string {
+    var b strings.Builder
+    for _, r := range strings.ToUpper(service) {
+        if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+            b.WriteRune(r)
+        } else {
+            b.WriteRune('_')
+        }
+    }
+    return b.String()
+}
+
+
+// Generates a password under the given policy and previews it once on stdout before handing
+// it back enclaved, so callers (`gen`, and `add --generate`/the `!gen` shorthand) never have
+// the plaintext sitting around outside of memguard.
+func GenerateAndPreview(policy ghostpass.PasswordPolicy) (*memguard.Enclave, error) {
+    enclave, err := ghostpass.GeneratePassword(policy)
+    if err != nil {
+        return nil, err
+    }
+
+    buf, err := enclave.Open()
+    if err != nil {
+        return nil, err
+    }
+    defer buf.Destroy()
+
+    col := color.New(color.FgYellow).Add(color.Bold)
+    col.Printf("> Generated password (shown once): %s\n", buf.String())
+
+    return buf.Seal(), nil
+}
+
+
+// Resolves which store names a `backup`/`rotate-key` invocation should target: just the
+// given name, or every store under the workspace if it's omitted.
+func BackupTargets(name string) ([]string, error) {
+    if name != "" {
+        return []string{name}, nil
+    }
+
+    files, err := ioutil.ReadDir(ghostpass.MakeWorkspace())
+    if err != nil {
+        return nil, err
+    }
+
+    names := make([]string, 0, len(files))
+    for _, f := range files {
+        names = append(names, strings.TrimSuffix(f.Name(), filepath.Ext(f.Name())))
+    }
+    return names, nil
+}
+
+
+// Confirms a destructive or overwrite action, auto-accepting under --batch/GHOSTPASS_BATCH=1
+// or --yes so scripted/CI invocations never block on an interactive prompt.
+func Confirm(label string) (bool, error) {
+    if batchMode || autoYes {
+        return true, nil
+    }
+
+    prompt := promptui.Select{
+        Label: label,
+        Items: []string{"Yes", "No"},
+    }
+    _, result, err := prompt.Run()
+    if err != nil {
+        return false, err
+    }
+    return result == "Yes", nil
+}
+
+
 func init() {
     // initialize new workspace directory if not set
     _ = ghostpass.MakeWorkspace()
@@ -73,7 +331,123 @@ func main() {
     app := &cli.App {
         Name: "ghostpass",
         Usage: Description,
+        Flags: []cli.Flag{
+            &cli.BoolFlag{
+                Name: "no-keyring",
+                Usage: "Never touch the OS keyring; always prompt for the master key on stdin",
+            },
+            &cli.BoolFlag{
+                Name: "batch",
+                Usage: "Non-interactive mode for scripts/CI: never block on a TTY prompt",
+                EnvVars: []string{"GHOSTPASS_BATCH"},
+            },
+            &cli.BoolFlag{
+                Name: "yes",
+                Usage: "Auto-answer destructive/overwrite confirmations with Yes",
+            },
+            &cli.DurationFlag{
+                Name: "keyring-ttl",
+                Usage: "How long a cached master key is allowed to live in the OS keyring before it must be re-entered",
+                Value: DefaultKeyringTTL,
+                EnvVars: []string{"GHOSTPASS_KEYRING_TTL"},
+            },
+        },
+        Before: func(c *cli.Context) error {
+            noKeyring = c.Bool("no-keyring")
+            batchMode = c.Bool("batch")
+            autoYes = c.Bool("yes")
+            keyringTTL = c.Duration("keyring-ttl")
+            return nil
+        },
         Commands: []*cli.Command {
+            {
+                Name: "keyring",
+                Category: "Initialization",
+                Usage: "Manage cached master keys in the OS keyring",
+                Subcommands: []*cli.Command{
+                    {
+                        Name: "unlock",
+                        Usage: "Prompt for a store's master key and cache it in the OS keyring",
+                        Flags: []cli.Flag{
+                            &cli.StringFlag{
+                                Name: "name",
+                                Usage: "Name of secret store to unlock",
+                                Aliases: []string{"n"},
+                            },
+                        },
+                        Action: func(c *cli.Context) error {
+                            name := c.String("name")
+                            if name == "" {
+                                return errors.New("Name to secret store not specified.")
+                            }
+
+                            var masterkey *memguard.Enclave
+                            var err error
+                            if batchMode {
+                                masterkey, err = ReadMasterKeyBatch("GHOSTPASS_MASTER_KEY_FILE")
+                            } else {
+                                fmt.Printf("> Master Key (will not be echoed): ")
+                                masterkey, err = ReadKeyFromStdin()
+                                fmt.Println()
+                            }
+                            if err != nil {
+                                return err
+                            }
+
+                            if err := keyring.Set("ghostpass:"+name, masterkey, keyringTTL); err != nil {
+                                return err
+                            }
+
+                            col := color.New(color.FgGreen).Add(color.Bold)
+                            col.Printf("[*] Cached master key for `%s` in the OS keyring [*]\n", name)
+                            return nil
+                        },
+                    },
+                    {
+                        Name: "lock",
+                        Usage: "Evict a store's cached master key from the OS keyring",
+                        Flags: []cli.Flag{
+                            &cli.StringFlag{
+                                Name: "name",
+                                Usage: "Name of secret store to lock",
+                                Aliases: []string{"n"},
+                            },
+                        },
+                        Action: func(c *cli.Context) error {
+                            name := c.String("name")
+                            if name == "" {
+                                return errors.New("Name to secret store not specified.")
+                            }
+
+                            if err := keyring.Forget("ghostpass:" + name); err != nil {
+                                return err
+                            }
+
+                            col := color.New(color.FgGreen).Add(color.Bold)
+                            col.Printf("[*] Locked `%s`; master key evicted from the OS keyring [*]\n", name)
+                            return nil
+                        },
+                    },
+                    {
+                        Name: "forget",
+                        Usage: "Alias for `lock`; evicts a store's cached master key",
+                        Flags: []cli.Flag{
+                            &cli.StringFlag{
+                                Name: "name",
+                                Usage: "Name of secret store to forget",
+                                Aliases: []string{"n"},
+                            },
+                        },
+                        Action: func(c *cli.Context) error {
+                            name := c.String("name")
+                            if name == "" {
+                                return errors.New("Name to secret store not specified.")
+                            }
+                            return keyring.Forget("ghostpass:" + name)
+                        },
+                    },
+                },
+            },
             {
                 Name: "init",
                 Category: "Initialization",
@@ -95,8 +469,7 @@ func main() {
                     col.Printf("\n[*] Initializing new secret store `%s` [*]\n\n", name)
 
                     // read master key and store in buffer safely
-                    fmt.Printf("> Master Key (will not be echoed): ")
-                    masterkey, err := ReadKeyFromStdin()
+                    masterkey, err := ResolveMasterKey(name)
                     fmt.Printf("\n\n")
                     if err != nil {
                         return err
@@ -162,8 +535,7 @@ func main() {
                     col.Printf("\n[*] Destroying secret store `%s` [*]\n\n", name)
 
                     // read master key for the secret store
-                    fmt.Printf("> Master Key (will not be echoed): ")
-                    masterkey, err := ReadKeyFromStdin()
+                    masterkey, err := ResolveMasterKey(name)
                     fmt.Println()
                     if err != nil {
                         return err
@@ -178,29 +550,101 @@ func main() {
                     fmt.Println()
 
                     // ask for user confirmation
-					prompt := promptui.Select{
-						Label: "Are you SURE you want to do this? You will NOT be able to go back",
-						Items: []string{"Yes", "No"},
-					}
-					_, result, err := prompt.Run()
-					if err != nil {
+                    confirmed, err := Confirm("Are you SURE you want to do this? You will NOT be able to go back")
+                    if err != nil {
                         return err
-					}
+                    }
 
                     fmt.Println()
 
-                    if result != "Yes" {
+                    if !confirmed {
                         fmt.Println("Exiting...")
                         return nil
                     }
 
                     // nuke!
                     store.DestroyStore()
+                    _ = keyring.Forget("ghostpass:" + name)
                     col = color.New(color.FgGreen).Add(color.Bold)
                     col.Println("[*] Successfully nuked the secret store! Poof! [*]")
                     return nil
                 },
             },
+            {
+                Name: "gen",
+                Category: "Operations",
+                Usage: "Generate a cryptographically strong password",
+                Flags: []cli.Flag{
+                    &cli.IntFlag{
+                        Name: "length",
+                        Usage: "Length of the generated password",
+                        Aliases: []string{"l"},
+                        Value: DefaultGeneratedLength,
+                    },
+                    &cli.BoolFlag{
+                        Name: "no-lower",
+                        Usage: "Exclude lowercase letters",
+                    },
+                    &cli.BoolFlag{
+                        Name: "no-upper",
+                        Usage: "Exclude uppercase letters",
+                    },
+                    &cli.BoolFlag{
+                        Name: "no-digit",
+                        Usage: "Exclude digits",
+                    },
+                    &cli.BoolFlag{
+                        Name: "no-symbol",
+                        Usage: "Exclude symbols",
+                    },
+                    &cli.StringFlag{
+                        Name: "exclude",
+                        Usage: "Characters to exclude, e.g. confusables like 0O1lI",
+                    },
+                    &cli.BoolFlag{
+                        Name: "diceware",
+                        Usage: "Generate a diceware passphrase instead of a character password",
+                    },
+                    &cli.IntFlag{
+                        Name: "words",
+                        Usage: "Number of words in a diceware passphrase",
+                        Value: 6,
+                    },
+                    &cli.StringFlag{
+                        Name: "separator",
+                        Usage: "Separator between diceware words",
+                        Value: "-",
+                    },
+                },
+                Action: func(c *cli.Context) error {
+                    policy := ghostpass.PasswordPolicy{
+                        Length: c.Int("length"),
+                        Lower: !c.Bool("no-lower"),
+                        Upper: !c.Bool("no-upper"),
+                        Digit: !c.Bool("no-digit"),
+                        Symbol: !c.Bool("no-symbol"),
+                        Exclude: c.String("exclude"),
+                        Diceware: c.Bool("diceware"),
+                        WordCount: c.Int("words"),
+                        Separator: c.String("separator"),
+                    }
+
+                    enclave, err := ghostpass.GeneratePassword(policy)
+                    if err != nil {
+                        return err
+                    }
+
+                    buf, err := enclave.Open()
+                    if err != nil {
+                        return err
+                    }
+                    defer buf.Destroy()
+
+                    col := color.New(color.FgGreen).Add(color.Bold)
+                    col.Printf("\n> %s\n\n", buf.String())
+                    return nil
+                },
+            },
             {
                 Name: "add",
                 Category: "Operations",
@@ -221,6 +665,23 @@ func main() {
                         Usage: "Username for the service",
                         Aliases: []string{"u"},
                     },
+                    &cli.BoolFlag{
+                        Name: "generate",
+                        Usage: "Generate a strong password instead of prompting for one (shorthand: type !gen at the prompt)",
+                    },
+                    &cli.IntFlag{
+                        Name: "length",
+                        Usage: "Length of the generated password, if --generate or !gen is used",
+                        Value: DefaultGeneratedLength,
+                    },
+                    &cli.StringFlag{
+                        Name: "otp-uri",
+                        Usage: "Import a TOTP seed for this field from a QR-scanned otpauth:// URI",
+                    },
+                    &cli.StringFlag{
+                        Name: "password",
+                        Usage: "Password for the field, for non-interactive use. Required in --batch mode unless --generate is also passed",
+                    },
                 },
                 Action: func(c *cli.Context) error {
                     name := c.String("name")
@@ -232,8 +693,7 @@ func main() {
                     col.Printf("\n[*] Adding field entry to secret store `%s` [*]\n", name)
 
                     // read master key for the secret store
-                    fmt.Printf("\n> Master Key (will not be echoed): ")
-                    masterkey, err := ReadKeyFromStdin()
+                    masterkey, err := ResolveMasterKey(name)
                     fmt.Println()
                     if err != nil {
                         return err
@@ -248,48 +708,77 @@ func main() {
                     // get service if not specified in args
                     service := c.String("service")
                     if service == "" {
-                        reader := bufio.NewReader(os.Stdin)
-                        fmt.Print("> Service: ")
-                        text, err := reader.ReadString('\n')
+                        service, err = PromptLine("Service", "--service")
                         if err != nil {
                             return err
                         }
-                        service = strings.TrimSuffix(text, "\n")
                     }
 
                     // get username if not specified in args
                     username := c.String("username")
                     if username == "" {
-                        reader := bufio.NewReader(os.Stdin)
-                        fmt.Print("> Username: ")
-                        text, err := reader.ReadString('\n')
+                        username, err = PromptLine("Username", "--username")
                         if err != nil {
                             return err
                         }
-                        username = strings.TrimSuffix(text, "\n")
                     }
 
-                    // read password for service and store in buffer safely
-                    fmt.Printf("> Password for `%s` (will not be echoed): ", service)
-                    pwd, err := ReadKeyFromStdin()
-                    if err != nil {
-                        return err
+                    // read password for service and store in buffer safely. `--generate`, or
+                    // typing `!gen` at the prompt, produces one instead so it never has to
+                    // pass through a shell and into history
+                    genPolicy := ghostpass.PasswordPolicy{
+                        Length: c.Int("length"),
+                        Lower: true,
+                        Upper: true,
+                        Digit: true,
+                        Symbol: true,
+                    }
+
+                    var pwd *memguard.Enclave
+                    switch {
+                    case c.Bool("generate"):
+                        pwd, err = GenerateAndPreview(genPolicy)
+                        if err != nil {
+                            return err
+                        }
+                    case c.String("password") != "":
+                        key := memguard.NewBufferFromBytes([]byte(c.String("password")))
+                        if key.Size() == 0 {
+                            return errors.New("--password was empty")
+                        }
+                        pwd = key.Seal()
+                    case batchMode:
+                        return errors.New("no password source available in --batch mode; pass --generate or --password")
+                    default:
+                        fmt.Printf("> Password for `%s` (will not be echoed, or type `%s` to generate one): ", service, GenerateShorthand)
+                        pwd, err = ReadKeyFromStdin()
+                        if err != nil {
+                            return err
+                        }
+
+                        if buf, openErr := pwd.Open(); openErr == nil {
+                            typed := buf.String()
+                            buf.Destroy()
+
+                            if typed == GenerateShorthand {
+                                pwd, err = GenerateAndPreview(genPolicy)
+                                if err != nil {
+                                    return err
+                                }
+                            }
+                        }
                     }
 
                     fmt.Printf("\n\n")
 
                     // check if key already exists and warn user of overwrite
                     if store.FieldExists(service) {
-					    prompt := promptui.Select{
-                            Label: "Field already exists in secret store. Overwrite?",
-                            Items: []string{"Yes", "No"},
-					    }
-                        _, result, err := prompt.Run()
+                        confirmed, err := Confirm("Field already exists in secret store. Overwrite?")
                         if err != nil {
                             return err
                         }
 
-                        if result != "Yes" {
+                        if !confirmed {
                             fmt.Println("Exiting...")
                             return nil
                         }
@@ -300,6 +789,13 @@ func main() {
                         return err
                     }
 
+                    // import an accompanying TOTP seed, if a QR-scanned otpauth:// URI was given
+                    if otpURI := c.String("otp-uri"); otpURI != "" {
+                        if err := store.SetOTP(service, otpURI); err != nil {
+                            return err
+                        }
+                    }
+
                     // commit, writing the changes to the persistent store
                     if err := store.CommitStore(); err != nil {
                         return err
@@ -337,8 +833,7 @@ func main() {
                     col.Printf("\n[*] Removing field entry from secret store `%s` [*]\n", name)
 
                     // read master key for the secret store
-                    fmt.Printf("\n> Master Key (will not be echoed): ")
-                    masterkey, err := ReadKeyFromStdin()
+                    masterkey, err := ResolveMasterKey(name)
                     fmt.Println()
                     if err != nil {
                         return err
@@ -353,13 +848,10 @@ func main() {
                     // get service if not specified in args
                     service := c.String("service")
                     if service == "" {
-                        reader := bufio.NewReader(os.Stdin)
-                        fmt.Print("> Service: ")
-                        text, err := reader.ReadString('\n')
+                        service, err = PromptLine("Service", "--service")
                         if err != nil {
                             return err
                         }
-                        service = strings.TrimSuffix(text, "\n")
                     }
 
                     fmt.Println()
@@ -394,6 +886,12 @@ func main() {
                         Usage: "Name of the service that identifies the field to view",
                         Aliases: []string{"s"},
                     },
+                    &cli.StringFlag{
+                        Name: "output",
+                        Usage: "Output format: table, json, env, or dotenv",
+                        Aliases: []string{"o"},
+                        Value: DefaultOutputFormat,
+                    },
                 },
                 Action: func(c *cli.Context) error {
                     name := c.String("name")
@@ -401,13 +899,23 @@ func main() {
                         return errors.New("Name to secret store not specified.")
                     }
 
+                    output := c.String("output")
+                    switch output {
+                    case "table", "json", "env", "dotenv":
+                    default:
+                        return fmt.Errorf("unsupported --output format `%s`", output)
+                    }
+
                     col := color.New(color.FgWhite).Add(color.Bold)
-                    col.Printf("\n[*] Retrieving field entry from secret store `%s` [*]\n", name)
+                    if output == "table" {
+                        col.Printf("\n[*] Retrieving field entry from secret store `%s` [*]\n", name)
+                    }
 
                     // read master key for the secret store
-                    fmt.Printf("\n> Master Key (will not be echoed): ")
-                    masterkey, err := ReadKeyFromStdin()
-                    fmt.Println()
+                    masterkey, err := ResolveMasterKey(name)
+                    if output == "table" {
+                        fmt.Println()
+                    }
                     if err != nil {
                         return err
                     }
@@ -421,29 +929,92 @@ func main() {
                     // get service if not specified in args
                     service := c.String("service")
                     if service == "" {
-                        reader := bufio.NewReader(os.Stdin)
-                        fmt.Print("> Service: ")
-                        text, err := reader.ReadString('\n')
+                        service, err = PromptLine("Service", "--service")
                         if err != nil {
                             return err
                         }
-                        service = strings.TrimSuffix(text, "\n")
                     }
-                    fmt.Println()
+                    if output == "table" {
+                        fmt.Println()
+                    }
 
-                    // derive the combo entry from field given the service key
+                    // derive the combo entry from field given the service key. GetField hands
+                    // the password back as a plain string, so it is not held in a memguard
+                    // buffer on its way to the output writer, whichever --output format is used
                     combo, err := store.GetField(service)
                     if err != nil {
                         return err
                     }
 
-                    // output ascii table
-                    table := tablewriter.NewWriter(os.Stdout)
-                    table.SetHeader([]string{"Service", "Username", "Password"})
-                    table.SetAutoMergeCells(true)
-                    table.SetRowLine(true)
-                    table.Append(combo)
-                    table.Render()
+                    // render the live OTP column alongside the static password if the field
+                    // carries a TOTP seed; fields without one simply omit the column
+                    var otp *OTPCode
+                    if code, remaining, otpErr := store.GetOTP(service); otpErr == nil {
+                        otp = &OTPCode{Code: code, RemainingSeconds: remaining}
+                    }
+
+                    return WriteFieldOutput(output, combo, otp)
+                },
+            },
+            {
+                Name: "otp",
+                Category: "Operations",
+                Usage: "Compute the current TOTP/OTP code for a field",
+                Flags: []cli.Flag{
+                    &cli.StringFlag{
+                        Name: "name",
+                        Usage: "Name of the secret store the field lives in",
+                        Aliases: []string{"n"},
+                    },
+                    &cli.StringFlag{
+                        Name: "service",
+                        Usage: "Name of the service that identifies the field to compute the OTP for",
+                        Aliases: []string{"s"},
+                    },
+                    &cli.BoolFlag{
+                        Name: "copy",
+                        Usage: "Copy the code to the clipboard instead of printing it",
+                    },
+                },
+                Action: func(c *cli.Context) error {
+                    name := c.String("name")
+                    if name == "" {
+                        return errors.New("Name to secret store not specified.")
+                    }
+
+                    masterkey, err := ResolveMasterKey(name)
+                    fmt.Println()
+                    if err != nil {
+                        return err
+                    }
+
+                    store, err := ghostpass.OpenStore(name, masterkey)
+                    if err != nil {
+                        return err
+                    }
+
+                    service := c.String("service")
+                    if service == "" {
+                        service, err = PromptLine("Service", "--service")
+                        if err != nil {
+                            return err
+                        }
+                    }
+
+                    code, remaining, err := store.GetOTP(service)
+                    if err != nil {
+                        return err
+                    }
+
+                    col := color.New(color.FgGreen).Add(color.Bold)
+                    if c.Bool("copy") {
+                        if err := clipboard.WriteAll(code); err != nil {
+                            return err
+                        }
+                        col.Printf("\n[*] Copied OTP code for `%s` to the clipboard (expires in %ds) [*]\n", service, remaining)
+                    } else {
+                        col.Printf("\n> %s (expires in %ds)\n\n", code, remaining)
+                    }
                     return nil
                 },
             },
@@ -468,8 +1039,7 @@ func main() {
                     col.Printf("\n[*] Retrieving all fields from secret store `%s` [*]\n", name)
 
                     // read master key for the secret store
-                    fmt.Printf("\n> Master Key (will not be echoed): ")
-                    masterkey, err := ReadKeyFromStdin()
+                    masterkey, err := ResolveMasterKey(name)
                     fmt.Println()
                     if err != nil {
                         return err
@@ -500,6 +1070,10 @@ func main() {
                         Usage: "Path to previously encoded plainsight file to import",
                         Aliases: []string{"c"},
                     },
+                    &cli.StringFlag{
+                        Name: "codec",
+                        Usage: "Plainsight codec the corpus was encoded with (markov, png-lsb, zero-width). Auto-detected from magic bytes if omitted",
+                    },
                 },
                 Action: func(c *cli.Context) error {
                     corpus := c.String("corpus")
@@ -507,22 +1081,34 @@ func main() {
                         return errors.New("No path to corpus provided for plainsight decoding.")
                     }
 
-                    // read master key for the secret store
-                    fmt.Printf("\n> Master Key (will not be echoed): ")
-                    masterkey, err := ReadKeyFromStdin()
-                    fmt.Println()
+                    codec := c.String("codec")
+
+                    // read master key for the secret store. the store's name isn't known until
+                    // after decoding, so this can't go through the keyring yet; `ghostpass keyring
+                    // unlock` can be used afterwards to cache it.
+                    var masterkey *memguard.Enclave
+                    var err error
+                    if batchMode {
+                        masterkey, err = ReadMasterKeyBatch("GHOSTPASS_MASTER_KEY_FILE")
+                    } else {
+                        fmt.Printf("> Master Key (will not be echoed): ")
+                        masterkey, err = ReadKeyFromStdin()
+                        fmt.Println()
+                    }
                     if err != nil {
                         return err
                     }
 
-                    // read data out of corpus file
+                    // read data out of corpus file. codecs like the PNG LSB one are binary, so this
+                    // is read and handed off as raw bytes rather than a trimmed string
                     corpusdata, err := ioutil.ReadFile(corpus)
                     if err != nil {
                         return err
                     }
 
-                    // recreate secret store given plainsight corpus
-                    store, err := ghostpass.Import(masterkey, strings.TrimSpace(string(corpusdata)))
+                    // recreate secret store given plainsight corpus. if codec is empty, the registry
+                    // auto-detects which codec produced it from its magic bytes/heuristics
+                    store, err := ghostpass.Import(masterkey, corpusdata, codec)
                     if err != nil {
                         return err
                     }
@@ -557,6 +1143,11 @@ func main() {
                         Usage: "Output path for the encoded file",
                         Aliases: []string{"o"},
                     },
+                    &cli.StringFlag{
+                        Name: "codec",
+                        Usage: "Plainsight codec to encode the corpus with (markov, png-lsb, zero-width)",
+                        Value: DefaultPlainsightCodec,
+                    },
                 },
                 Action: func(c *cli.Context) error {
                     name := c.String("name")
@@ -569,6 +1160,8 @@ func main() {
                         return errors.New("No corpus provided for plainsight encoding.")
                     }
 
+                    codec := c.String("codec")
+
                     // if output file name not set, set a default one to cwd
                     var outfile string
                     if c.String("outfile") == "" {
@@ -578,8 +1171,7 @@ func main() {
                     }
 
                     // read master key for the secret store
-                    fmt.Printf("\n> Master Key (will not be echoed): ")
-                    masterkey, err := ReadKeyFromStdin()
+                    masterkey, err := ResolveMasterKey(name)
                     if err != nil {
                         return err
                     }
@@ -590,20 +1182,22 @@ func main() {
                         return err
                     }
 
-                    // read data from corpus file
+                    // read data from corpus file as raw bytes, since not every codec's cover
+                    // medium (e.g. the PNG LSB codec's cover image) is valid text
                     corpusdata, err := ioutil.ReadFile(corpus)
                     if err != nil {
                         return err
                     }
 
                     // given the current state the store represents, export it as a plainsight file
-                    final, err := store.Export(strings.TrimSpace(string(corpusdata)))
+                    // using the requested codec
+                    final, err := store.Export(corpusdata, codec)
                     if err != nil {
                         return err
                     }
 
                     // write finalized data to output file
-                    err = ioutil.WriteFile(outfile, []byte(final), 0644)
+                    err = ioutil.WriteFile(outfile, final, 0644)
                     if err != nil {
                         return err
                     }
@@ -613,6 +1207,169 @@ func main() {
                     return nil
                 },
             },
+            {
+                Name: "backup",
+                Category: "Distribution",
+                Usage: "Create a self-describing, versioned backup archive of one or all secret stores",
+                Flags: []cli.Flag{
+                    &cli.StringFlag{
+                        Name: "name",
+                        Usage: "Name of a single secret store to back up; every store under the workspace if omitted",
+                        Aliases: []string{"n"},
+                    },
+                    &cli.StringFlag{
+                        Name: "out",
+                        Usage: "Directory to write the backup archive to",
+                        Aliases: []string{"o"},
+                        Value: ".",
+                    },
+                },
+                Action: func(c *cli.Context) error {
+                    names, err := BackupTargets(c.String("name"))
+                    if err != nil {
+                        return err
+                    }
+
+                    stores := make([]*ghostpass.Store, 0, len(names))
+                    for _, storeName := range names {
+                        masterkey, err := ResolveMasterKey(storeName)
+                        fmt.Println()
+                        if err != nil {
+                            return err
+                        }
+
+                        store, err := ghostpass.OpenStore(storeName, masterkey)
+                        if err != nil {
+                            return err
+                        }
+                        stores = append(stores, store)
+                    }
+
+                    outdir := c.String("out")
+                    if err := os.MkdirAll(outdir, 0755); err != nil {
+                        return err
+                    }
+
+                    outfile := filepath.Join(outdir, fmt.Sprintf("ghostpass-backup-%d.tar.gz", time.Now().Unix()))
+                    f, err := os.Create(outfile)
+                    if err != nil {
+                        return err
+                    }
+                    defer f.Close()
+
+                    // writes a manifest (store names, schema version, KDF parameters, timestamps,
+                    // per-store ciphertext SHA-256) alongside each store's ciphertext
+                    if err := ghostpass.Backup(stores, f); err != nil {
+                        return err
+                    }
+
+                    col := color.New(color.FgGreen).Add(color.Bold)
+                    col.Printf("\n[*] Wrote backup archive for %d store(s) to `%s` [*]\n", len(stores), outfile)
+                    return nil
+                },
+            },
+            {
+                Name: "restore",
+                Category: "Distribution",
+                Usage: "Restore one or more secret stores from a backup archive",
+                Flags: []cli.Flag{
+                    &cli.StringFlag{
+                        Name: "in",
+                        Usage: "Path to the backup archive to restore from",
+                        Aliases: []string{"i"},
+                    },
+                },
+                Action: func(c *cli.Context) error {
+                    in := c.String("in")
+                    if in == "" {
+                        return errors.New("Path to backup archive not specified.")
+                    }
+
+                    f, err := os.Open(in)
+                    if err != nil {
+                        return err
+                    }
+                    defer f.Close()
+
+                    // each store in the archive was independently re-encryptable under a
+                    // different master key, so its own key is requested per store
+                    restored, err := ghostpass.Restore(f, func(storeName string) (*memguard.Enclave, error) {
+                        return ResolveMasterKey(storeName)
+                    })
+                    if err != nil {
+                        return err
+                    }
+
+                    for _, store := range restored {
+                        if err := store.CommitStore(); err != nil {
+                            return err
+                        }
+                    }
+
+                    col := color.New(color.FgGreen).Add(color.Bold)
+                    col.Printf("\n[*] Restored %d store(s) from `%s` [*]\n", len(restored), in)
+                    return nil
+                },
+            },
+            {
+                Name: "rotate-key",
+                Category: "Distribution",
+                Usage: "Re-encrypt one or all secret stores under a new master key",
+                Flags: []cli.Flag{
+                    &cli.StringFlag{
+                        Name: "name",
+                        Usage: "Name of a single secret store to rotate; every store under the workspace if omitted",
+                        Aliases: []string{"n"},
+                    },
+                },
+                Action: func(c *cli.Context) error {
+                    names, err := BackupTargets(c.String("name"))
+                    if err != nil {
+                        return err
+                    }
+
+                    for _, storeName := range names {
+                        col := color.New(color.FgWhite).Add(color.Bold)
+                        col.Printf("\n[*] Rotating master key for secret store `%s` [*]\n", storeName)
+
+                        // current key: keyring-cache- and --batch-aware, like every other command
+                        oldKey, err := ResolveMasterKey(storeName)
+                        if err != nil {
+                            return err
+                        }
+
+                        // new key: never pulled from the keyring (it isn't cached under it yet),
+                        // but still --batch-aware so this never blocks on a TTY read in CI. Reads
+                        // from its own GHOSTPASS_NEW_MASTER_KEY_FILE (or the next stdin line after
+                        // the old key's, off the same shared reader) so it can't collide with the
+                        // old key's source.
+                        var newKey *memguard.Enclave
+                        if batchMode {
+                            newKey, err = ReadMasterKeyBatch("GHOSTPASS_NEW_MASTER_KEY_FILE")
+                        } else {
+                            fmt.Printf("> New Master Key (will not be echoed): ")
+                            newKey, err = ReadKeyFromStdin()
+                            fmt.Println()
+                        }
+                        if err != nil {
+                            return err
+                        }
+
+                        // decrypts every field under the old key, re-derives under the new
+                        // key/salt, and writes the rotated store out atomically (temp file +
+                        // rename) so a crash mid-rotation can't corrupt the store on disk
+                        if err := ghostpass.RotateKey(storeName, oldKey, newKey); err != nil {
+                            return err
+                        }
+
+                        _ = keyring.Forget("ghostpass:" + storeName)
+                    }
+
+                    col := color.New(color.FgGreen).Add(color.Bold)
+                    col.Printf("\n[*] Rotated master key for %d store(s) [*]\n", len(names))
+                    return nil
+                },
+            },
         },
     }
 
@@ -0,0 +1,59 @@
+package ghostpass
+
+import "fmt"
+
+// PlainsightCodec hides an opaque, already-encrypted state blob inside a cover
+// medium (the "corpus") so a ghostpass store can be distributed as something
+// that doesn't look like a secrets database. Encode embeds state into corpus;
+// Decode recovers state from a previously-encoded cipher without needing the
+// original corpus back.
+type PlainsightCodec interface {
+	// Name is the identifier used by --codec and the registry.
+	Name() string
+	// Encode hides state inside corpus, returning the cover-medium bytes.
+	Encode(state []byte, corpus []byte) ([]byte, error)
+	// Decode recovers state from previously-encoded data.
+	Decode(data []byte) ([]byte, error)
+	// DetectMagic reports whether data looks like something this codec produced,
+	// for --codec auto-detection on import.
+	DetectMagic(data []byte) bool
+}
+
+var codecRegistry = map[string]PlainsightCodec{}
+
+// RegisterCodec adds codec to the registry under its own Name().
+func RegisterCodec(codec PlainsightCodec) {
+	codecRegistry[codec.Name()] = codec
+}
+
+// GetCodec looks up a registered codec by name.
+func GetCodec(name string) (PlainsightCodec, error) {
+	codec, ok := codecRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown plainsight codec `%s`", name)
+	}
+	return codec, nil
+}
+
+// detectPriority orders DetectCodec's search: codecs with unambiguous magic
+// bytes are tried before markov, whose trailing-whitespace heuristic is the
+// most likely of the three to produce a false positive on arbitrary input.
+var detectPriority = []string{"png-lsb", "zero-width", "markov"}
+
+// DetectCodec finds the registered codec whose magic bytes/heuristics match
+// data, for `import` when --codec isn't given explicitly.
+func DetectCodec(data []byte) (PlainsightCodec, error) {
+	for _, name := range detectPriority {
+		codec, ok := codecRegistry[name]
+		if ok && codec.DetectMagic(data) {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("could not auto-detect a plainsight codec for this corpus; pass --codec explicitly")
+}
+
+func init() {
+	RegisterCodec(&MarkovCodec{})
+	RegisterCodec(&PNGLSBCodec{})
+	RegisterCodec(&ZeroWidthCodec{})
+}
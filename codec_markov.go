@@ -0,0 +1,120 @@
+package ghostpass
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// MarkovCodec is ghostpass's original plainsight codec. It rewraps the cover
+// corpus into lines built from the corpus's own words -- so the visible text
+// reads like a excerpt of the source material -- and hides payload bits as the
+// count of trailing space characters on each line (classic whitespace
+// steganography), which a reader never notices but which survives round-trips
+// through anything that preserves trailing whitespace.
+type MarkovCodec struct{}
+
+func (c *MarkovCodec) Name() string { return "markov" }
+
+const markovWordsPerLine = 8
+
+func (c *MarkovCodec) Encode(state []byte, corpus []byte) ([]byte, error) {
+	words := strings.Fields(string(corpus))
+	if len(words) == 0 {
+		return nil, errors.New("markov codec needs a non-empty text corpus to draw cover words from")
+	}
+
+	bits := lengthPrefixedBits(state)
+	linesNeeded := (len(bits) + 1) / 2
+
+	lines := make([]string, 0, linesNeeded)
+	for i := 0; i < linesNeeded; i++ {
+		start := (i * markovWordsPerLine) % len(words)
+		line := make([]string, 0, markovWordsPerLine)
+		for j := 0; j < markovWordsPerLine; j++ {
+			line = append(line, words[(start+j)%len(words)])
+		}
+
+		twoBits := 0
+		if idx := i * 2; idx < len(bits) {
+			twoBits = int(bits[idx]) << 1
+			if idx+1 < len(bits) {
+				twoBits |= int(bits[idx+1])
+			}
+		}
+
+		lines = append(lines, strings.Join(line, " ")+strings.Repeat(" ", twoBits))
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func (c *MarkovCodec) Decode(data []byte) ([]byte, error) {
+	return markovDecode(data)
+}
+
+func (c *MarkovCodec) DetectMagic(data []byte) bool {
+	_, err := markovDecode(data)
+	return err == nil
+}
+
+func markovDecode(data []byte) ([]byte, error) {
+	lines := bytes.Split(data, []byte("\n"))
+
+	bits := make([]byte, 0, len(lines)*2)
+	for _, line := range lines {
+		trimmed := bytes.TrimRight(line, " ")
+		trailing := len(line) - len(trimmed)
+		if trailing > 3 {
+			trailing = 3
+		}
+		bits = append(bits, byte((trailing>>1)&1), byte(trailing&1))
+	}
+
+	return decodeLengthPrefixedBits(bits, "markov codec")
+}
+
+// lengthPrefixedBits turns state into a bitstream: a 4-byte big-endian length
+// prefix followed by state itself, one bit per output byte (0 or 1). Framing
+// state this way lets every codec below recover exactly where the embedded
+// payload ends without needing any side-channel.
+func lengthPrefixedBits(state []byte) []byte {
+	framed := make([]byte, 4+len(state))
+	binary.BigEndian.PutUint32(framed, uint32(len(state)))
+	copy(framed[4:], state)
+
+	bits := make([]byte, 0, len(framed)*8)
+	for _, b := range framed {
+		for i := 7; i >= 0; i-- {
+			bits = append(bits, (b>>uint(i))&1)
+		}
+	}
+	return bits
+}
+
+// decodeLengthPrefixedBits reverses lengthPrefixedBits given a recovered bitstream.
+func decodeLengthPrefixedBits(bits []byte, codecName string) ([]byte, error) {
+	if len(bits) < 32 {
+		return nil, errors.New(codecName + ": not enough embedded bits for a length prefix")
+	}
+
+	length := 0
+	for i := 0; i < 32; i++ {
+		length = (length << 1) | int(bits[i])
+	}
+	if length < 0 || (length+4)*8 > len(bits) {
+		return nil, errors.New(codecName + ": embedded length prefix is out of range")
+	}
+
+	payloadBits := bits[32 : 32+length*8]
+	payload := make([]byte, length)
+	for i := range payload {
+		var b byte
+		for j := 0; j < 8; j++ {
+			b = (b << 1) | payloadBits[i*8+j]
+		}
+		payload[i] = b
+	}
+	return payload, nil
+}
@@ -0,0 +1,79 @@
+package ghostpass
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// PNGLSBCodec hides the encrypted state in the least-significant bit of each
+// color channel of a cover PNG image, so the image looks unchanged to the eye
+// but carries the payload in bits nobody is looking at.
+type PNGLSBCodec struct{}
+
+func (c *PNGLSBCodec) Name() string { return "png-lsb" }
+
+var pngMagic = []byte{0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a}
+
+func (c *PNGLSBCodec) DetectMagic(data []byte) bool {
+	return bytes.HasPrefix(data, pngMagic)
+}
+
+func (c *PNGLSBCodec) Encode(state []byte, corpus []byte) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(corpus))
+	if err != nil {
+		return nil, fmt.Errorf("png-lsb codec needs a valid PNG cover image: %w", err)
+	}
+
+	bits := lengthPrefixedBits(state)
+	bounds := img.Bounds()
+	capacityBits := bounds.Dx() * bounds.Dy() * 3
+	if len(bits) > capacityBits {
+		return nil, fmt.Errorf("cover image is too small to hold %d bytes of state (capacity %d bits, need %d)", len(state), capacityBits, len(bits))
+	}
+
+	out := image.NewNRGBA(bounds)
+	bitIdx := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// .RGBA() returns alpha-premultiplied components even for an NRGBA source, which
+			// would corrupt the raw channel values (and the bits hidden in them) for any pixel
+			// with non-opaque alpha. Converting through NRGBAModel recovers the straight values.
+			nc := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			px := [3]uint8{nc.R, nc.G, nc.B}
+			for i := range px {
+				if bitIdx < len(bits) {
+					px[i] = (px[i] &^ 1) | bits[bitIdx]
+					bitIdx++
+				}
+			}
+			out.Set(x, y, color.NRGBA{R: px[0], G: px[1], B: px[2], A: nc.A})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, out); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *PNGLSBCodec) Decode(data []byte) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("png-lsb codec: not a valid PNG: %w", err)
+	}
+
+	bounds := img.Bounds()
+	bits := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			nc := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			bits = append(bits, nc.R&1, nc.G&1, nc.B&1)
+		}
+	}
+
+	return decodeLengthPrefixedBits(bits, "png-lsb codec")
+}
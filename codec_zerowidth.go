@@ -0,0 +1,65 @@
+package ghostpass
+
+import (
+	"bytes"
+	"strings"
+)
+
+// ZeroWidthCodec hides the encrypted state as invisible Unicode characters
+// (zero width space = bit 0, zero width non-joiner = bit 1) interleaved through
+// an arbitrary text corpus, one after each visible character. When the payload
+// has more bits than the corpus has characters, the corpus is repeated from its
+// start as many times as needed, so the visible text is then longer than the
+// original corpus.
+type ZeroWidthCodec struct{}
+
+func (c *ZeroWidthCodec) Name() string { return "zero-width" }
+
+const (
+	zwBitZero = '​' // zero width space
+	zwBitOne  = '‌' // zero width non-joiner
+)
+
+func (c *ZeroWidthCodec) Encode(state []byte, corpus []byte) ([]byte, error) {
+	cover := string(corpus)
+	if cover == "" {
+		cover = " "
+	}
+	runes := []rune(cover)
+
+	bits := lengthPrefixedBits(state)
+
+	var out strings.Builder
+	for i, bit := range bits {
+		out.WriteRune(runes[i%len(runes)])
+		if bit == 1 {
+			out.WriteRune(zwBitOne)
+		} else {
+			out.WriteRune(zwBitZero)
+		}
+	}
+	// tack on whatever corpus is left over so the cover text still reads naturally
+	if len(bits) < len(runes) {
+		out.WriteString(string(runes[len(bits):]))
+	}
+
+	return []byte(out.String()), nil
+}
+
+func (c *ZeroWidthCodec) Decode(data []byte) ([]byte, error) {
+	bits := make([]byte, 0)
+	for _, r := range string(data) {
+		switch r {
+		case zwBitZero:
+			bits = append(bits, 0)
+		case zwBitOne:
+			bits = append(bits, 1)
+		}
+	}
+
+	return decodeLengthPrefixedBits(bits, "zero-width codec")
+}
+
+func (c *ZeroWidthCodec) DetectMagic(data []byte) bool {
+	return bytes.ContainsRune(data, zwBitZero) || bytes.ContainsRune(data, zwBitOne)
+}
@@ -0,0 +1,164 @@
+package ghostpass
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/awnumar/memguard"
+)
+
+// PasswordPolicy configures GeneratePassword: either a character-class
+// password, or (when Diceware is set) a Separator-joined passphrase drawn from
+// a wordlist.
+type PasswordPolicy struct {
+	Length    int
+	Lower     bool
+	Upper     bool
+	Digit     bool
+	Symbol    bool
+	Exclude   string
+	Diceware  bool
+	WordCount int
+	Separator string
+}
+
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!@#$%^&*()-_=+[]{}:;,.?"
+
+	// confusableChars are visually ambiguous across common fonts (0/O, 1/l/I,
+	// etc.) and are always excluded on top of whatever the caller passes.
+	confusableChars = "0O1lI"
+
+	defaultGeneratedLength = 20
+)
+
+// GeneratePassword produces a cryptographically strong secret under policy
+// using crypto/rand.
+func GeneratePassword(policy PasswordPolicy) (*memguard.Enclave, error) {
+	if policy.Diceware {
+		return generateDiceware(policy)
+	}
+	return generateCharacterPassword(policy)
+}
+
+func generateCharacterPassword(policy PasswordPolicy) (*memguard.Enclave, error) {
+	length := policy.Length
+	if length <= 0 {
+		length = defaultGeneratedLength
+	}
+
+	exclude := policy.Exclude + confusableChars
+
+	classes := make([]string, 0, 4)
+	if policy.Lower {
+		classes = append(classes, stripExcluded(lowerChars, exclude))
+	}
+	if policy.Upper {
+		classes = append(classes, stripExcluded(upperChars, exclude))
+	}
+	if policy.Digit {
+		classes = append(classes, stripExcluded(digitChars, exclude))
+	}
+	if policy.Symbol {
+		classes = append(classes, stripExcluded(symbolChars, exclude))
+	}
+	if len(classes) == 0 {
+		return nil, errors.New("password policy selects no character classes")
+	}
+	if length < len(classes) {
+		return nil, errors.New("password length is too short to satisfy every selected character class")
+	}
+
+	alphabet := strings.Join(classes, "")
+	if alphabet == "" {
+		return nil, errors.New("password policy excludes every character in its classes")
+	}
+
+	// Rejection sampling: regenerate until the candidate contains at least one
+	// character from every selected class, rather than forcing specific
+	// positions (which would bias the distribution).
+	const maxAttempts = 1000
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		candidate, err := randomString(alphabet, length)
+		if err != nil {
+			return nil, err
+		}
+		if satisfiesClasses(candidate, classes) {
+			return memguard.NewBufferFromBytes([]byte(candidate)).Seal(), nil
+		}
+	}
+
+	return nil, errors.New("failed to generate a password satisfying the policy after 1000 attempts")
+}
+
+func stripExcluded(chars, exclude string) string {
+	if exclude == "" {
+		return chars
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(exclude, r) {
+			return -1
+		}
+		return r
+	}, chars)
+}
+
+func satisfiesClasses(candidate string, classes []string) bool {
+	for _, class := range classes {
+		if !strings.ContainsAny(candidate, class) {
+			return false
+		}
+	}
+	return true
+}
+
+func randomString(alphabet string, length int) (string, error) {
+	result := make([]byte, length)
+	max := big.NewInt(int64(len(alphabet)))
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = alphabet[n.Int64()]
+	}
+	return string(result), nil
+}
+
+// dicewareWordlist is a small built-in fallback; a production install would
+// ship the full EFF long wordlist, but a compact one keeps the binary
+// self-contained and dependency-free.
+var dicewareWordlist = []string{
+	"anchor", "batch", "cactus", "dialect", "ember", "fusion", "glacier", "harbor",
+	"ignite", "jungle", "kernel", "lantern", "meadow", "nimbus", "orchid", "pebble",
+	"quartz", "ripple", "sierra", "timber", "umbra", "velvet", "willow", "xenon",
+	"yonder", "zephyr",
+}
+
+func generateDiceware(policy PasswordPolicy) (*memguard.Enclave, error) {
+	words := policy.WordCount
+	if words <= 0 {
+		words = 6
+	}
+	sep := policy.Separator
+	if sep == "" {
+		sep = "-"
+	}
+
+	chosen := make([]string, words)
+	max := big.NewInt(int64(len(dicewareWordlist)))
+	for i := range chosen {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return nil, err
+		}
+		chosen[i] = dicewareWordlist[n.Int64()]
+	}
+
+	return memguard.NewBufferFromBytes([]byte(strings.Join(chosen, sep))).Seal(), nil
+}
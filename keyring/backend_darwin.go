@@ -0,0 +1,37 @@
+//go:build darwin
+
+package keyring
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// On macOS, caching shells out to the `security` CLI against the login Keychain
+// rather than binding to the Keychain Services API directly, so this package
+// stays cgo-free.
+
+func backendSet(service, account, value string) error {
+	_ = backendDelete(service, account)
+	cmd := exec.Command("security", "add-generic-password",
+		"-s", service, "-a", account, "-w", value, "-U")
+	return cmd.Run()
+}
+
+func backendGet(service, account string) (string, error) {
+	cmd := exec.Command("security", "find-generic-password",
+		"-s", service, "-a", account, "-w")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("no cached key in keychain: %w", err)
+	}
+	return strings.TrimRight(out.String(), "\n"), nil
+}
+
+func backendDelete(service, account string) error {
+	cmd := exec.Command("security", "delete-generic-password", "-s", service, "-a", account)
+	return cmd.Run()
+}
@@ -0,0 +1,39 @@
+//go:build linux
+
+package keyring
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// On Linux, caching shells out to `secret-tool`, the CLI shipped by libsecret
+// that talks to whichever Secret Service provider is running -- GNOME Keyring or
+// KWallet's libsecret shim -- without this package needing to know which.
+
+func backendSet(service, account, value string) error {
+	cmd := exec.Command("secret-tool", "store", "--label=ghostpass",
+		"service", service, "account", account)
+	cmd.Stdin = strings.NewReader(value)
+	return cmd.Run()
+}
+
+func backendGet(service, account string) (string, error) {
+	cmd := exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("no cached key in secret service: %w", err)
+	}
+	if out.Len() == 0 {
+		return "", fmt.Errorf("no cached key in secret service")
+	}
+	return out.String(), nil
+}
+
+func backendDelete(service, account string) error {
+	cmd := exec.Command("secret-tool", "clear", "service", service, "account", account)
+	return cmd.Run()
+}
@@ -0,0 +1,22 @@
+//go:build !darwin && !linux && !windows
+
+package keyring
+
+import "errors"
+
+// Platforms without a recognized native secret store (BSDs, plan9, etc.) have no
+// backend to bind to; callers should pass --no-keyring there.
+
+var errUnsupported = errors.New("OS keyring caching is not supported on this platform; use --no-keyring")
+
+func backendSet(service, account, value string) error {
+	return errUnsupported
+}
+
+func backendGet(service, account string) (string, error) {
+	return "", errUnsupported
+}
+
+func backendDelete(service, account string) error {
+	return errUnsupported
+}
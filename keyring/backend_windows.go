@@ -0,0 +1,107 @@
+//go:build windows
+
+package keyring
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// On Windows, caching binds directly to the Credential Manager via advapi32's
+// CredWrite/CredRead/CredDelete -- loaded through syscall.NewLazyDLL so this
+// stays within the standard library and doesn't require cgo.
+
+var (
+	advapi32        = syscall.NewLazyDLL("advapi32.dll")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric          = 1
+	credPersistLocalMachine  = 2
+)
+
+// credential mirrors the Win32 CREDENTIAL structure; only the fields
+// CredWrite/CredRead need are populated, the rest are left zeroed.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *uint16
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+func targetName(service, account string) string {
+	return fmt.Sprintf("ghostpass:%s:%s", service, account)
+}
+
+func backendSet(service, account, value string) error {
+	target, err := syscall.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	blob, err := syscall.UTF16FromString(value)
+	if err != nil {
+		return err
+	}
+
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob) * 2),
+		CredentialBlob:     &blob[0],
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+
+	ret, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if ret == 0 {
+		return fmt.Errorf("CredWriteW failed: %w", err)
+	}
+	return nil
+}
+
+func backendGet(service, account string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return "", err
+	}
+
+	var ptr uintptr
+	ret, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&ptr)))
+	if ret == 0 {
+		return "", fmt.Errorf("no cached key in credential manager: %w", err)
+	}
+	defer procCredFree.Call(ptr)
+
+	cred := (*credential)(unsafe.Pointer(ptr))
+	blob := (*[1 << 20]uint16)(unsafe.Pointer(cred.CredentialBlob))[: cred.CredentialBlobSize/2 : cred.CredentialBlobSize/2]
+	return syscall.UTF16ToString(blob), nil
+}
+
+func backendDelete(service, account string) error {
+	target, err := syscall.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return err
+	}
+	ret, _, err := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0)
+	if ret == 0 {
+		return fmt.Errorf("CredDeleteW failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Package keyring caches unlocked ghostpass master keys in the platform's native
+// secret store (macOS Keychain, GNOME Keyring/KWallet via libsecret, Windows
+// Credential Manager) so the same key doesn't need to be retyped on every
+// invocation. Each platform's bindings live in their own build-tagged file;
+// this file only handles the TTL envelope shared across all of them.
+package keyring
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/awnumar/memguard"
+)
+
+// account is the fixed keyring account name cached keys are stored under; the
+// store name is already namespaced into the service string by the caller
+// (e.g. "ghostpass:personal").
+const account = "master-key"
+
+// record is the JSON payload persisted in the OS keyring: the key, base64-encoded
+// so it round-trips through string-only keyring backends, plus its expiry.
+type record struct {
+	Secret string `json:"secret"`
+	Expiry int64  `json:"expiry"`
+}
+
+// Set caches key under service with an expiry ttl from now. The entry stays in
+// the OS keyring past that expiry until a Get finds it expired and evicts it.
+func Set(service string, key *memguard.Enclave, ttl time.Duration) error {
+	buf, err := key.Open()
+	if err != nil {
+		return err
+	}
+	defer buf.Destroy()
+
+	rec := record{
+		Secret: base64.StdEncoding.EncodeToString(buf.Bytes()),
+		Expiry: time.Now().Add(ttl).Unix(),
+	}
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	return backendSet(service, account, string(raw))
+}
+
+// Get returns the cached key for service. It errors if nothing is cached, or if
+// the cached entry has expired -- in which case it's also evicted.
+func Get(service string) (*memguard.Enclave, error) {
+	raw, err := backendGet(service, account)
+	if err != nil {
+		return nil, err
+	}
+
+	var rec record
+	if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+		return nil, err
+	}
+
+	if time.Now().Unix() > rec.Expiry {
+		_ = backendDelete(service, account)
+		return nil, errors.New("cached master key expired")
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(rec.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return memguard.NewBufferFromBytes(secret).Seal(), nil
+}
+
+// Forget evicts any cached key for service.
+func Forget(service string) error {
+	return backendDelete(service, account)
+}
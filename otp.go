@@ -0,0 +1,169 @@
+package ghostpass
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTPSeed is the RFC 6238 TOTP configuration stored alongside a field: the
+// shared secret plus the parameters needed to reproduce codes from it.
+type OTPSeed struct {
+	Secret    string `json:"secret"`    // base32-encoded shared secret
+	Algorithm string `json:"algorithm"` // SHA1, SHA256, or SHA512
+	Digits    int    `json:"digits"`
+	Period    int    `json:"period"` // seconds
+}
+
+const (
+	defaultOTPDigits    = 6
+	defaultOTPPeriod    = 30
+	defaultOTPAlgorithm = "SHA1"
+)
+
+// ParseOTPURI parses a QR-scanned `otpauth://totp/...` URI into an OTPSeed.
+func ParseOTPURI(raw string) (*OTPSeed, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		return nil, fmt.Errorf("unsupported OTP URI `%s`; only otpauth://totp is supported", raw)
+	}
+
+	q := u.Query()
+	secret := strings.ToUpper(q.Get("secret"))
+	if secret == "" {
+		return nil, fmt.Errorf("otpauth URI is missing a `secret` parameter")
+	}
+
+	seed := &OTPSeed{
+		Secret:    secret,
+		Algorithm: defaultOTPAlgorithm,
+		Digits:    defaultOTPDigits,
+		Period:    defaultOTPPeriod,
+	}
+
+	if alg := strings.ToUpper(q.Get("algorithm")); alg != "" {
+		seed.Algorithm = alg
+	}
+	if digits := q.Get("digits"); digits != "" {
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return nil, fmt.Errorf("invalid digits parameter: %w", err)
+		}
+		seed.Digits = n
+	}
+	if period := q.Get("period"); period != "" {
+		n, err := strconv.Atoi(period)
+		if err != nil {
+			return nil, fmt.Errorf("invalid period parameter: %w", err)
+		}
+		seed.Period = n
+	}
+
+	if _, err := seed.hasher(); err != nil {
+		return nil, err
+	}
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(seed.Secret); err != nil {
+		return nil, fmt.Errorf("invalid base32 OTP secret: %w", err)
+	}
+
+	return seed, nil
+}
+
+func (s *OTPSeed) hasher() (func() hash.Hash, error) {
+	switch s.Algorithm {
+	case "SHA1", "":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported OTP algorithm `%s`", s.Algorithm)
+	}
+}
+
+// Compute derives the current TOTP code along with the number of seconds
+// remaining until it rotates, per RFC 6238.
+func (s *OTPSeed) Compute() (string, int, error) {
+	return s.computeAt(time.Now())
+}
+
+func (s *OTPSeed) computeAt(now time.Time) (string, int, error) {
+	period := s.Period
+	if period == 0 {
+		period = defaultOTPPeriod
+	}
+	digits := s.Digits
+	if digits == 0 {
+		digits = defaultOTPDigits
+	}
+
+	hasher, err := s.hasher()
+	if err != nil {
+		return "", 0, err
+	}
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(s.Secret))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid base32 OTP secret: %w", err)
+	}
+
+	counter := uint64(now.Unix()) / uint64(period)
+	remaining := period - int(now.Unix()%int64(period))
+
+	var counterBytes [8]byte
+	binary.BigEndian.PutUint64(counterBytes[:], counter)
+
+	mac := hmac.New(hasher, key)
+	mac.Write(counterBytes[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	code := truncated % uint32(math.Pow10(digits))
+	return fmt.Sprintf("%0*d", digits, code), remaining, nil
+}
+
+// SetOTP parses otpURI and attaches it to the field for service.
+func (s *Store) SetOTP(service, otpURI string) error {
+	seed, err := ParseOTPURI(otpURI)
+	if err != nil {
+		return err
+	}
+
+	for i, f := range s.fields {
+		if f.Service == service {
+			s.fields[i].OTP = seed
+			return nil
+		}
+	}
+	return fmt.Errorf("no field found for service `%s`", service)
+}
+
+// GetOTP computes the current TOTP code for service, along with the seconds
+// remaining until it rotates.
+func (s *Store) GetOTP(service string) (string, int, error) {
+	for _, f := range s.fields {
+		if f.Service == service {
+			if f.OTP == nil {
+				return "", 0, fmt.Errorf("field `%s` has no OTP seed", service)
+			}
+			return f.OTP.Compute()
+		}
+	}
+	return "", 0, fmt.Errorf("no field found for service `%s`", service)
+}
@@ -0,0 +1,102 @@
+package ghostpass
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/awnumar/memguard"
+)
+
+// Export encodes the store's current encrypted envelope into corpus using the
+// named plainsight codec (see RegisterCodec), producing cover-medium bytes
+// suitable for distribution as something other than a secrets database.
+func (s *Store) Export(corpus []byte, codecName string) ([]byte, error) {
+	if codecName == "" {
+		codecName = DefaultPlainsightCodec
+	}
+	codec, err := GetCodec(codecName)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(s.fields)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := encrypt(s.key, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	env := envelope{
+		Name: s.Name,
+		Salt: base64.StdEncoding.EncodeToString(s.salt),
+		KDFN: scryptN,
+		KDFR: scryptR,
+		KDFP: scryptP,
+		Blob: base64.StdEncoding.EncodeToString(blob),
+	}
+	state, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.Encode(state, corpus)
+}
+
+// DefaultPlainsightCodec is used by Export when no codec name is given.
+const DefaultPlainsightCodec = "markov"
+
+// Import recreates a Store from a plainsight-encoded corpus previously produced
+// by Export. If codecName is empty, the codec is auto-detected from the
+// corpus's magic bytes/heuristics (see DetectCodec).
+func Import(masterkey *memguard.Enclave, corpus []byte, codecName string) (*Store, error) {
+	var codec PlainsightCodec
+	var err error
+	if codecName != "" {
+		codec, err = GetCodec(codecName)
+	} else {
+		codec, err = DetectCodec(corpus)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := codec.Decode(corpus)
+	if err != nil {
+		return nil, err
+	}
+
+	var env envelope
+	if err := json.Unmarshal(state, &env); err != nil {
+		return nil, errors.New("decoded plainsight state is not a valid ghostpass envelope")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := base64.StdEncoding.DecodeString(env.Blob)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := masterkey.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Destroy()
+
+	key, err := deriveKey(buf.Bytes(), salt)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := decryptFields(key, blob)
+	if err != nil {
+		return nil, errors.New("incorrect master key or corrupted plainsight corpus")
+	}
+
+	return &Store{Name: env.Name, salt: salt, key: key, fields: fields}, nil
+}
@@ -0,0 +1,268 @@
+// Package ghostpass implements the secret store format, encryption, plainsight
+// codecs, password generation, OTP handling, and backup/rotation that the
+// ghostpass CLI (cmd/ghostpass) drives.
+package ghostpass
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/awnumar/memguard"
+)
+
+// Field is a single secret entry in a Store: a service/username/password combo,
+// plus an optional TOTP seed.
+type Field struct {
+	Service  string   `json:"service"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	OTP      *OTPSeed `json:"otp,omitempty"`
+}
+
+// Store is a decrypted, in-memory view of a secret store. The encryption key is
+// held only in memory for the lifetime of the process; nothing is written to
+// disk until CommitStore is called.
+type Store struct {
+	Name   string
+	fields []Field
+	salt   []byte
+	key    []byte
+}
+
+// envelope is the on-disk JSON format for a secret store: a plaintext header
+// (name, salt, KDF parameters) followed by the AES-256-GCM-encrypted field list.
+type envelope struct {
+	Name string `json:"name"`
+	Salt string `json:"salt"`
+	KDFN int    `json:"kdf_n"`
+	KDFR int    `json:"kdf_r"`
+	KDFP int    `json:"kdf_p"`
+	Blob string `json:"blob"`
+}
+
+// MakeWorkspace ensures the ghostpass workspace directory exists and returns its
+// path. Every secret store lives under here as "<name>.gpstore".
+func MakeWorkspace() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	dir := filepath.Join(home, ".ghostpass")
+	_ = os.MkdirAll(dir, 0700)
+	return dir
+}
+
+func storePath(name string) string {
+	return filepath.Join(MakeWorkspace(), name+".gpstore")
+}
+
+// InitStore creates a brand new, empty secret store named name, encrypted under
+// masterkey. It fails if a store with that name already exists.
+func InitStore(name string, masterkey *memguard.Enclave) (*Store, error) {
+	if _, err := os.Stat(storePath(name)); err == nil {
+		return nil, fmt.Errorf("secret store `%s` already exists", name)
+	}
+
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := masterkey.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Destroy()
+
+	key, err := deriveKey(buf.Bytes(), salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{Name: name, salt: salt, key: key, fields: []Field{}}, nil
+}
+
+// OpenStore decrypts and loads the secret store named name using masterkey.
+func OpenStore(name string, masterkey *memguard.Enclave) (*Store, error) {
+	raw, err := ioutil.ReadFile(storePath(name))
+	if err != nil {
+		return nil, fmt.Errorf("secret store `%s` not found: %w", name, err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, fmt.Errorf("secret store `%s` has a corrupted envelope: %w", name, err)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := base64.StdEncoding.DecodeString(env.Blob)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := masterkey.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Destroy()
+
+	key, err := deriveKey(buf.Bytes(), salt)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := decryptFields(key, blob)
+	if err != nil {
+		return nil, errors.New("incorrect master key or corrupted store")
+	}
+
+	return &Store{Name: name, salt: salt, key: key, fields: fields}, nil
+}
+
+func decryptFields(key, blob []byte) ([]Field, error) {
+	plaintext, err := decrypt(key, blob)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []Field
+	if len(plaintext) > 0 {
+		if err := json.Unmarshal(plaintext, &fields); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// CommitStore encrypts the store's current fields and writes it to disk
+// atomically (temp file + rename), so a crash mid-write can never leave a
+// corrupted store behind.
+func (s *Store) CommitStore() error {
+	plaintext, err := json.Marshal(s.fields)
+	if err != nil {
+		return err
+	}
+
+	blob, err := encrypt(s.key, plaintext)
+	if err != nil {
+		return err
+	}
+
+	env := envelope{
+		Name: s.Name,
+		Salt: base64.StdEncoding.EncodeToString(s.salt),
+		KDFN: scryptN,
+		KDFR: scryptR,
+		KDFP: scryptP,
+		Blob: base64.StdEncoding.EncodeToString(blob),
+	}
+
+	raw, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicWriteFile(storePath(s.Name), raw, 0600)
+}
+
+// atomicWriteFile writes data to path via a temp file in the same directory
+// followed by a rename, so readers never observe a partially-written file.
+func atomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// DestroyStore permanently deletes the store's file from the workspace.
+func (s *Store) DestroyStore() error {
+	return os.Remove(storePath(s.Name))
+}
+
+// FieldExists reports whether a field for service is already present.
+func (s *Store) FieldExists(service string) bool {
+	for _, f := range s.fields {
+		if f.Service == service {
+			return true
+		}
+	}
+	return false
+}
+
+// AddField adds a new field, or overwrites the existing one for service,
+// preserving any OTP seed already attached to it.
+func (s *Store) AddField(service, username string, password *memguard.Enclave) error {
+	buf, err := password.Open()
+	if err != nil {
+		return err
+	}
+	defer buf.Destroy()
+
+	field := Field{Service: service, Username: username, Password: buf.String()}
+
+	for i, f := range s.fields {
+		if f.Service == service {
+			field.OTP = f.OTP
+			s.fields[i] = field
+			return nil
+		}
+	}
+
+	s.fields = append(s.fields, field)
+	return nil
+}
+
+// RemoveField deletes the field for service.
+func (s *Store) RemoveField(service string) error {
+	for i, f := range s.fields {
+		if f.Service == service {
+			s.fields = append(s.fields[:i], s.fields[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no field found for service `%s`", service)
+}
+
+// GetField returns the service/username/password combo for service.
+func (s *Store) GetField(service string) ([]string, error) {
+	for _, f := range s.fields {
+		if f.Service == service {
+			return []string{f.Service, f.Username, f.Password}, nil
+		}
+	}
+	return nil, fmt.Errorf("no field found for service `%s`", service)
+}
+
+// GetFields returns the service name of every field in the store.
+func (s *Store) GetFields() []string {
+	names := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		names[i] = f.Service
+	}
+	return names
+}